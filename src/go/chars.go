@@ -12,6 +12,7 @@ const (
 	QUMARK_CODE = 63 // ?
 	SEMCOL_CODE = 59 // ;
 	COLON_CODE  = 58 // :
+	SLASH_CODE  = 47 // /
 )
 
 // isDecimalDigit checks if ch is a decimal digit
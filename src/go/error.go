@@ -0,0 +1,44 @@
+package prejspy
+
+import "fmt"
+
+// ParseError indicates that parsing an expression failed. It implements the
+// error interface and carries the rune offset, and the corresponding
+// 1-indexed line and column, of the offending character, following the
+// design of the position-carrying errors produced by go/scanner.
+type ParseError struct {
+	Message string
+	Index   int
+	Line    int
+	Column  int
+	Source  string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s at character %d (line %d, column %d)", err.Message, err.Index, err.Line, err.Column)
+}
+
+// newParseError builds a ParseError for the character at index within source,
+// computing the corresponding 1-indexed line and column.
+func newParseError(message string, index int, source string) *ParseError {
+	line, column := 1, 1
+	for i, r := range []rune(source) {
+		if i >= index {
+			break
+		}
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return &ParseError{
+		Message: message,
+		Index:   index,
+		Line:    line,
+		Column:  column,
+		Source:  source,
+	}
+}
@@ -0,0 +1,55 @@
+package prejspy
+
+import "testing"
+
+func TestParseSafe(t *testing.T) {
+	parser := NewPreJSPy()
+
+	if _, err := parser.ParseSafe("1 +"); err == nil {
+		t.Fatal("ParseSafe(\"1 +\") expected an error, got nil")
+	}
+
+	expr, err := parser.ParseSafe("1 + 2")
+	if err != nil {
+		t.Fatalf("ParseSafe(\"1 + 2\") returned unexpected error: %v", err)
+	}
+	if expr == nil {
+		t.Fatal("ParseSafe(\"1 + 2\") returned nil Expression")
+	}
+}
+
+func TestParseSafeErrorDetails(t *testing.T) {
+	parser := NewPreJSPy()
+
+	_, err := parser.ParseSafe("1 +")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected *ParseError, got %T", err)
+	}
+	if parseErr.Index != strlen("1 +") {
+		t.Errorf("Index = %d, want %d", parseErr.Index, strlen("1 +"))
+	}
+	if parseErr.Line != 1 || parseErr.Column != strlen("1 +")+1 {
+		t.Errorf("Line/Column = %d/%d, want 1/%d", parseErr.Line, parseErr.Column, strlen("1 +")+1)
+	}
+}
+
+func TestParsePanicsOnError(t *testing.T) {
+	parser := NewPreJSPy()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Parse(\"1 +\") expected a panic")
+		}
+		if _, ok := r.(*ParseError); !ok {
+			t.Errorf("expected panic value of type *ParseError, got %T", r)
+		}
+	}()
+
+	parser.Parse("1 +")
+}
@@ -0,0 +1,565 @@
+package prejspy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EvalError indicates that evaluating an Expression against a Context failed.
+// Node is the sub-expression that was being evaluated when the error occurred.
+type EvalError struct {
+	Message string
+	Node    Expression
+}
+
+func (err *EvalError) Error() string {
+	return err.Message
+}
+
+func newEvalError(node Expression, format string, args ...interface{}) *EvalError {
+	return &EvalError{Message: fmt.Sprintf(format, args...), Node: node}
+}
+
+// Evaluator evaluates Expressions produced by PreJSPy.Parse against a runtime Context.
+//
+// Identifiers, and the objects reached via MemberExpression, are resolved by
+// looking them up inside Context, reflecting into maps, structs, slices and
+// arrays as required. CallExpression callees are resolved the same way and
+// then invoked via reflection, so any Go function found in Context -- not
+// just func(args ...interface{}) (interface{}, error) -- can be called.
+type Evaluator struct {
+	context map[string]interface{}
+}
+
+// NewEvaluator creates a new Evaluator that resolves identifiers against context.
+func NewEvaluator(context map[string]interface{}) *Evaluator {
+	return &Evaluator{context: context}
+}
+
+// GetContext returns the context used for evaluation.
+func (e *Evaluator) GetContext() map[string]interface{} {
+	return e.context
+}
+
+// SetContext sets the context used for evaluation.
+func (e *Evaluator) SetContext(context map[string]interface{}) {
+	e.context = context
+}
+
+// Eval evaluates expr against the Evaluator's Context.
+func (e *Evaluator) Eval(expr Expression) (interface{}, error) {
+	switch node := expr.(type) {
+	case Compound:
+		return e.evalCompound(node)
+	case Identifier:
+		return e.evalIdentifier(node)
+	case Literal:
+		return node.Value, nil
+	case RegexLiteral:
+		return e.evalRegex(node)
+	case ArrayExpression:
+		return e.evalArray(node)
+	case UnaryExpression:
+		return e.evalUnary(node)
+	case BinaryExpression:
+		return e.evalBinary(node)
+	case ConditionalExpression:
+		return e.evalConditional(node)
+	case MemberExpression:
+		return e.evalMember(node)
+	case CallExpression:
+		return e.evalCall(node)
+	default:
+		return nil, newEvalError(expr, "cannot evaluate node of type %s", expr.Type())
+	}
+}
+
+func (e *Evaluator) evalCompound(node Compound) (interface{}, error) {
+	var result interface{}
+	for _, stmt := range node.Body {
+		value, err := e.Eval(stmt)
+		if err != nil {
+			return nil, err
+		}
+		result = value
+	}
+	return result, nil
+}
+
+func (e *Evaluator) evalIdentifier(node Identifier) (interface{}, error) {
+	value, ok := e.context[node.Name]
+	if !ok {
+		return nil, newEvalError(node, "undefined identifier %q", node.Name)
+	}
+	return value, nil
+}
+
+// evalRegex compiles node's pattern and flags via Go's regexp package.
+// The JavaScript flags i, m and s are translated to the equivalent inline
+// flags understood by regexp.Compile; other JavaScript flags (g, u, y) have
+// no Go regexp equivalent and are ignored.
+func (e *Evaluator) evalRegex(node RegexLiteral) (interface{}, error) {
+	var goFlags string
+	for _, flag := range node.Flags {
+		switch flag {
+		case 'i', 'm', 's':
+			goFlags += string(flag)
+		}
+	}
+
+	pattern := node.Pattern
+	if goFlags != "" {
+		pattern = "(?" + goFlags + ")" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, newEvalError(node, "cannot compile regular expression %q: %s", node.Pattern, err.Error())
+	}
+	return re, nil
+}
+
+func (e *Evaluator) evalArray(node ArrayExpression) (interface{}, error) {
+	elements := make([]interface{}, len(node.Elements))
+	for i, el := range node.Elements {
+		value, err := e.Eval(el)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = value
+	}
+	return elements, nil
+}
+
+func (e *Evaluator) evalUnary(node UnaryExpression) (interface{}, error) {
+	arg, err := e.Eval(node.Argument)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.Operator {
+	case "-":
+		n, ok := toNumber(arg)
+		if !ok {
+			return nil, newEvalError(node, "cannot negate non-numeric value %v", arg)
+		}
+		return -n, nil
+	case "+":
+		n, ok := toNumber(arg)
+		if !ok {
+			return nil, newEvalError(node, "cannot convert %v to a number", arg)
+		}
+		return n, nil
+	case "!":
+		return !toBool(arg), nil
+	case "~":
+		n, ok := toNumber(arg)
+		if !ok {
+			return nil, newEvalError(node, "cannot apply ~ to non-numeric value %v", arg)
+		}
+		return float64(^int32(n)), nil
+	default:
+		return nil, newEvalError(node, "unknown unary operator %q", node.Operator)
+	}
+}
+
+func (e *Evaluator) evalBinary(node BinaryExpression) (interface{}, error) {
+	// && and || short-circuit, so the right-hand side must not be evaluated eagerly.
+	if node.Operator == "&&" || node.Operator == "||" {
+		left, err := e.Eval(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		if node.Operator == "&&" {
+			if !toBool(left) {
+				return left, nil
+			}
+			return e.Eval(node.Right)
+		}
+		if toBool(left) {
+			return left, nil
+		}
+		return e.Eval(node.Right)
+	}
+
+	left, err := e.Eval(node.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Eval(node.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node.Operator {
+	case "+":
+		if _, ok := left.(string); ok {
+			return toString(left) + toString(right), nil
+		}
+		if _, ok := right.(string); ok {
+			return toString(left) + toString(right), nil
+		}
+		ln, lok := toNumber(left)
+		rn, rok := toNumber(right)
+		if !lok || !rok {
+			return nil, newEvalError(node, "cannot add %v and %v", left, right)
+		}
+		return ln + rn, nil
+	case "-", "*", "/", "%":
+		ln, lok := toNumber(left)
+		rn, rok := toNumber(right)
+		if !lok || !rok {
+			return nil, newEvalError(node, "cannot apply %s to %v and %v", node.Operator, left, right)
+		}
+		switch node.Operator {
+		case "-":
+			return ln - rn, nil
+		case "*":
+			return ln * rn, nil
+		case "/":
+			if rn == 0 {
+				return nil, newEvalError(node, "division by zero")
+			}
+			return ln / rn, nil
+		default: // "%"
+			if rn == 0 {
+				return nil, newEvalError(node, "division by zero")
+			}
+			return math.Mod(ln, rn), nil
+		}
+	case "==":
+		return looseEquals(left, right), nil
+	case "!=":
+		return !looseEquals(left, right), nil
+	case "===":
+		return strictEquals(left, right), nil
+	case "!==":
+		return !strictEquals(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compareValues(node, left, right)
+	case "|", "^", "&", "<<", ">>", ">>>":
+		return bitwiseValues(node, left, right)
+	default:
+		return nil, newEvalError(node, "unknown binary operator %q", node.Operator)
+	}
+}
+
+func compareValues(node BinaryExpression, left, right interface{}) (interface{}, error) {
+	if ls, ok := left.(string); ok {
+		if rs, ok := right.(string); ok {
+			switch node.Operator {
+			case "<":
+				return ls < rs, nil
+			case "<=":
+				return ls <= rs, nil
+			case ">":
+				return ls > rs, nil
+			default: // ">="
+				return ls >= rs, nil
+			}
+		}
+	}
+
+	ln, lok := toNumber(left)
+	rn, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, newEvalError(node, "cannot compare %v and %v", left, right)
+	}
+	switch node.Operator {
+	case "<":
+		return ln < rn, nil
+	case "<=":
+		return ln <= rn, nil
+	case ">":
+		return ln > rn, nil
+	default: // ">="
+		return ln >= rn, nil
+	}
+}
+
+func bitwiseValues(node BinaryExpression, left, right interface{}) (interface{}, error) {
+	ln, lok := toNumber(left)
+	rn, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, newEvalError(node, "cannot apply %s to %v and %v", node.Operator, left, right)
+	}
+
+	l, r := int32(ln), int32(rn)
+	switch node.Operator {
+	case "|":
+		return float64(l | r), nil
+	case "^":
+		return float64(l ^ r), nil
+	case "&":
+		return float64(l & r), nil
+	case "<<":
+		return float64(l << uint32(r)), nil
+	case ">>":
+		return float64(l >> uint32(r)), nil
+	default: // ">>>"
+		return float64(uint32(l) >> uint32(r)), nil
+	}
+}
+
+func (e *Evaluator) evalConditional(node ConditionalExpression) (interface{}, error) {
+	test, err := e.Eval(node.Test)
+	if err != nil {
+		return nil, err
+	}
+	if toBool(test) {
+		return e.Eval(node.Consequent)
+	}
+	return e.Eval(node.Alternate)
+}
+
+func (e *Evaluator) evalMember(node MemberExpression) (interface{}, error) {
+	object, err := e.Eval(node.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	if node.Computed {
+		key, err = e.Eval(node.Property)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ident, ok := node.Property.(Identifier)
+		if !ok {
+			return nil, newEvalError(node, "non-computed member property must be an identifier")
+		}
+		key = ident.Name
+	}
+
+	value, err := lookupMember(object, key)
+	if err != nil {
+		return nil, newEvalError(node, "%s", err.Error())
+	}
+	return value, nil
+}
+
+// lookupMember resolves key on object via reflection, supporting maps, structs, slices and arrays.
+func lookupMember(object interface{}, key interface{}) (interface{}, error) {
+	if object == nil {
+		return nil, fmt.Errorf("cannot read property %v of null", key)
+	}
+
+	value := reflect.ValueOf(object)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		keyValue := reflect.ValueOf(key)
+		keyType := value.Type().Key()
+		if !keyValue.Type().AssignableTo(keyType) {
+			if !keyValue.Type().ConvertibleTo(keyType) {
+				return nil, fmt.Errorf("cannot use %v as a map key", key)
+			}
+			keyValue = keyValue.Convert(keyType)
+		}
+		result := value.MapIndex(keyValue)
+		if !result.IsValid() {
+			return nil, nil
+		}
+		return result.Interface(), nil
+	case reflect.Slice, reflect.Array:
+		index, ok := toNumber(key)
+		if !ok {
+			return nil, fmt.Errorf("cannot use %v as an array index", key)
+		}
+		i := int(index)
+		if i < 0 || i >= value.Len() {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		return value.Index(i).Interface(), nil
+	case reflect.Struct:
+		name := toString(key)
+		field := value.FieldByName(name)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("no such field %q", name)
+		}
+		return field.Interface(), nil
+	default:
+		return nil, fmt.Errorf("cannot access property %v of %v", key, object)
+	}
+}
+
+func (e *Evaluator) evalCall(node CallExpression) (interface{}, error) {
+	callee, err := e.Eval(node.Callee)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, len(node.Arguments))
+	for i, a := range node.Arguments {
+		value, err := e.Eval(a)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	if fn, ok := callee.(func(args ...interface{}) (interface{}, error)); ok {
+		return fn(args...)
+	}
+
+	value := reflect.ValueOf(callee)
+	if value.Kind() != reflect.Func {
+		return nil, newEvalError(node, "cannot call non-function value %v", callee)
+	}
+	if value.Type().IsVariadic() {
+		if len(args) < value.Type().NumIn()-1 {
+			return nil, newEvalError(node, "not enough arguments to call function")
+		}
+	} else if len(args) != value.Type().NumIn() {
+		return nil, newEvalError(node, "expected %d arguments, got %d", value.Type().NumIn(), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		paramType := value.Type().In(minInt(i, value.Type().NumIn()-1))
+		if value.Type().IsVariadic() && i >= value.Type().NumIn()-1 {
+			paramType = paramType.Elem()
+		}
+		if a == nil {
+			in[i] = reflect.Zero(paramType)
+			continue
+		}
+		argValue := reflect.ValueOf(a)
+		if !argValue.Type().AssignableTo(paramType) {
+			if !argValue.Type().ConvertibleTo(paramType) {
+				return nil, newEvalError(node, "cannot use %v as argument %d to function", a, i)
+			}
+			argValue = argValue.Convert(paramType)
+		}
+		in[i] = argValue
+	}
+
+	out, callErr := callFunc(value, in)
+	if callErr != nil {
+		return nil, newEvalError(node, "%s", callErr.Error())
+	}
+	switch len(out) {
+	case 0:
+		return nil, nil
+	case 1:
+		return out[0].Interface(), nil
+	default:
+		if err, ok := out[len(out)-1].Interface().(error); ok && err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	}
+}
+
+// callFunc invokes value with in, recovering from any panic raised by the
+// reflect call (e.g. a type mismatch reflect could not detect ahead of time)
+// and reporting it as an error instead.
+func callFunc(value reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return value.Call(in), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// toNumber attempts to coerce value to a float64, mirroring JavaScript's Number() semantics.
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	case nil:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// toBool coerces value to a bool, mirroring JavaScript's Boolean() semantics.
+func toBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case string:
+		return v != ""
+	case nil:
+		return false
+	default:
+		return true
+	}
+}
+
+// toString coerces value to a string, mirroring JavaScript's String() semantics.
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// strictEquals implements JavaScript's === operator for the value types produced by Parse/Eval.
+func strictEquals(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if reflect.TypeOf(left) != reflect.TypeOf(right) {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// looseEquals implements JavaScript's == operator for the value types produced by Parse/Eval.
+func looseEquals(left, right interface{}) bool {
+	if strictEquals(left, right) {
+		return true
+	}
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	ln, lok := toNumber(left)
+	rn, rok := toNumber(right)
+	if lok && rok {
+		return ln == rn
+	}
+	return toString(left) == toString(right)
+}
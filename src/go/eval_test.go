@@ -0,0 +1,106 @@
+package prejspy
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestEvaluator(t *testing.T) {
+	parser := NewPreJSPy()
+
+	tests := []struct {
+		name    string
+		input   string
+		context map[string]interface{}
+		want    interface{}
+	}{
+		{"addition", "1 + 2", nil, 3.0},
+		{"string concat", "'a' + 'b'", nil, "ab"},
+		{"string plus number", "'a' + 1", nil, "a1"},
+		{"precedence", "2 + 3 * 4", nil, 14.0},
+		{"ternary", "true ? 1 : 2", nil, 1.0},
+		{"identifier lookup", "x + 1", map[string]interface{}{"x": 41.0}, 42.0},
+		{"member expression", "obj.foo", map[string]interface{}{"obj": map[string]interface{}{"foo": "bar"}}, "bar"},
+		{"computed member", "arr[1]", map[string]interface{}{"arr": []interface{}{"a", "b", "c"}}, "b"},
+		{"strict equality", "1 === 1", nil, true},
+		{"loose equality", "1 == '1'", nil, true},
+		{"short circuit and", "false && (1/0 == 0)", nil, false},
+		{"short circuit or", "true || (1/0 == 0)", nil, true},
+		{"variadic call", "sum(1, 2, 3)", map[string]interface{}{
+			"sum": func(nums ...float64) float64 {
+				total := 0.0
+				for _, n := range nums {
+					total += n
+				}
+				return total
+			},
+		}, 6.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := parser.Parse(tt.input)
+			got, err := NewEvaluator(tt.context).Eval(expr)
+			if err != nil {
+				t.Fatalf("Eval(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatorRegex(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	expr := parser.Parse("/ab+c/i")
+	got, err := NewEvaluator(nil).Eval(expr)
+	if err != nil {
+		t.Fatalf("Eval(%q) returned error: %v", "/ab+c/i", err)
+	}
+	re, ok := got.(*regexp.Regexp)
+	if !ok {
+		t.Fatalf("Eval(%q) = %T, want *regexp.Regexp", "/ab+c/i", got)
+	}
+	if !re.MatchString("ABBC") {
+		t.Errorf("regexp %q did not match %q", re.String(), "ABBC")
+	}
+}
+
+func TestEvaluatorRegexCompileError(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	expr := parser.Parse("/(abc/")
+	if _, err := NewEvaluator(nil).Eval(expr); err == nil {
+		t.Errorf("Eval(%q) expected error, got nil", "/(abc/")
+	}
+}
+
+func TestEvaluatorErrors(t *testing.T) {
+	parser := NewPreJSPy()
+
+	tests := []struct {
+		name    string
+		input   string
+		context map[string]interface{}
+	}{
+		{"undefined identifier", "x", nil},
+		{"division by zero", "1 / 0", nil},
+		{"call non function", "x()", map[string]interface{}{"x": 1.0}},
+		{"call with wrong argument type", "f('notanumber')", map[string]interface{}{
+			"f": func(x int) int { return x + 1 },
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := parser.Parse(tt.input)
+			if _, err := NewEvaluator(tt.context).Eval(expr); err == nil {
+				t.Errorf("Eval(%q) expected error, got nil", tt.input)
+			}
+		})
+	}
+}
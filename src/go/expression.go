@@ -14,15 +14,46 @@ const (
 	BINARY_EXP      ASTKind = "BinaryExpression"
 	CONDITIONAL_EXP ASTKind = "ConditionalExpression"
 	ARRAY_EXP       ASTKind = "ArrayExpression"
+	REGEX_LITERAL   ASTKind = "RegexLiteral"
 )
 
 type Expression interface {
 	Type() ASTKind
+	// Pos returns the rune offset of the first character belonging to this node.
+	Pos() int
+	// End returns the rune offset just past the last character belonging to this node.
+	End() int
 	isExpression() // sealed
 }
 
+// loc holds the source position of an Expression, as included in its "loc" JSON field.
+type loc struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// nodeBase is embedded into every concrete Expression to record its source
+// position. locsEnabled mirrors PreJSPy.GetLocationsEnabled() at parse time,
+// and decides whether MarshalJSON emits a "loc" field.
+type nodeBase struct {
+	start, end  int
+	locsEnabled bool
+}
+
+func (n nodeBase) Pos() int { return n.start }
+func (n nodeBase) End() int { return n.end }
+
+// loc returns the node's source position, or nil if locations are disabled.
+func (n nodeBase) loc() *loc {
+	if !n.locsEnabled {
+		return nil
+	}
+	return &loc{Start: n.start, End: n.end}
+}
+
 // Compound is a compound expression consisting of multiple Expressions
 type Compound struct {
+	nodeBase
 	Body []Expression `json:"body"`
 }
 
@@ -34,14 +65,19 @@ func (expr Compound) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr Compound) String() string { return Unparse(expr) }
+
 // Idenfitifer is an identifier
 type Identifier struct {
+	nodeBase
 	Name string `json:"name"`
 }
 
@@ -53,14 +89,19 @@ func (expr Identifier) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr Identifier) String() string { return Unparse(expr) }
+
 // MemberExpression is a member expression
 type MemberExpression struct {
+	nodeBase
 	Computed bool       `json:"computed"`
 	Object   Expression `json:"object"`
 	Property Expression `json:"property"`
@@ -74,14 +115,19 @@ func (expr MemberExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr MemberExpression) String() string { return Unparse(expr) }
+
 // Literal is a literal
 type Literal struct {
+	nodeBase
 	Value interface{} `json:"value"` // dependening on the type of literal
 	Raw   string      `json:"raw"`
 }
@@ -94,14 +140,19 @@ func (expr Literal) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr Literal) String() string { return Unparse(expr) }
+
 // CallExpression is a call expression
 type CallExpression struct {
+	nodeBase
 	Arguments []Expression `json:"arguments"`
 	Callee    Expression   `json:"callee"`
 }
@@ -114,14 +165,19 @@ func (expr CallExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr CallExpression) String() string { return Unparse(expr) }
+
 // UnaryExpression is a unary expression
 type UnaryExpression struct {
+	nodeBase
 	Operator string     `json:"operator"`
 	Argument Expression `json:"argument"`
 }
@@ -134,14 +190,19 @@ func (expr UnaryExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr UnaryExpression) String() string { return Unparse(expr) }
+
 // BinaryExpression is a binary expression
 type BinaryExpression struct {
+	nodeBase
 	Operator string     `json:"operator"`
 	Left     Expression `json:"left"`
 	Right    Expression `json:"right"`
@@ -155,14 +216,19 @@ func (expr BinaryExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr BinaryExpression) String() string { return Unparse(expr) }
+
 // ConditionalExpression is a conditional expression
 type ConditionalExpression struct {
+	nodeBase
 	Test       Expression `json:"test"`
 	Consequent Expression `json:"consequent"`
 	Alternate  Expression `json:"alternate"`
@@ -176,14 +242,19 @@ func (expr ConditionalExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr ConditionalExpression) String() string { return Unparse(expr) }
+
 // ArrayExpression is an array expression
 type ArrayExpression struct {
+	nodeBase
 	Elements []Expression `json:"elements"`
 }
 
@@ -195,16 +266,49 @@ func (expr ArrayExpression) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		Fake
 		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
 	}{
 		Fake: Fake(expr),
 		Type: expr.Type(),
+		Loc:  expr.loc(),
 	})
 }
 
+func (expr ArrayExpression) String() string { return Unparse(expr) }
+
+// RegexLiteral is a regular-expression literal, e.g. `/abc/i`.
+// It is only produced when PreJSPy.SetRegexLiteralsEnabled(true) has been called.
+type RegexLiteral struct {
+	nodeBase
+	Pattern string `json:"pattern"`
+	Flags   string `json:"flags"`
+	Raw     string `json:"raw"`
+}
+
+func (RegexLiteral) Type() ASTKind { return REGEX_LITERAL }
+func (RegexLiteral) isExpression() {}
+
+func (expr RegexLiteral) MarshalJSON() ([]byte, error) {
+	type Fake RegexLiteral
+	return json.Marshal(struct {
+		Fake
+		Type ASTKind `json:"type"`
+		Loc  *loc    `json:"loc,omitempty"`
+	}{
+		Fake: Fake(expr),
+		Type: expr.Type(),
+		Loc:  expr.loc(),
+	})
+}
+
+func (expr RegexLiteral) String() string { return Unparse(expr) }
+
 type binaryOperator struct {
 	Value string
 	Prec  int
 }
 
 func (binaryOperator) Type() ASTKind { panic("implementation error") }
+func (binaryOperator) Pos() int      { panic("implementation error") }
+func (binaryOperator) End() int      { panic("implementation error") }
 func (binaryOperator) isExpression() {}
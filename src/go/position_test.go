@@ -0,0 +1,45 @@
+package prejspy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	parser := NewPreJSPy()
+
+	expr := parser.Parse("1 + foo.bar")
+	if got, want := expr.Pos(), 0; got != want {
+		t.Errorf("Pos() = %d, want %d", got, want)
+	}
+	if got, want := expr.End(), strlen("1 + foo.bar"); got != want {
+		t.Errorf("End() = %d, want %d", got, want)
+	}
+
+	binary := expr.(BinaryExpression)
+	if got, want := binary.Right.Pos(), strlen("1 + "); got != want {
+		t.Errorf("Right.Pos() = %d, want %d", got, want)
+	}
+}
+
+func TestLocationsEnabled(t *testing.T) {
+	parser := NewPreJSPy()
+
+	data, err := json.Marshal(parser.Parse("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "\"loc\"") {
+		t.Errorf("expected no loc field by default, got %s", data)
+	}
+
+	parser.SetLocationsEnabled(true)
+	data, err = json.Marshal(parser.Parse("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\"loc\":{\"start\":0,\"end\":1}") {
+		t.Errorf("expected loc field, got %s", data)
+	}
+}
@@ -17,6 +17,10 @@ type PreJSPy struct {
 	maxBinOpsLen int
 
 	tertiary bool
+
+	locations bool
+
+	regexLiterals bool
 }
 
 // Gets the constants to be used by this parser.
@@ -65,6 +69,32 @@ func (parser *PreJSPy) GetTertiaryOperatorEnabled() bool {
 	return parser.tertiary
 }
 
+// SetLocationsEnabled sets whether parsed Expressions include their source position
+// in JSON output. When enabled, Expression nodes marshal a "loc" field holding their
+// rune offsets. Pos and End report the position either way.
+func (parser *PreJSPy) SetLocationsEnabled(enabled bool) {
+	parser.locations = enabled
+}
+
+// GetLocationsEnabled returns whether parsed Expressions include their source position
+// in JSON output.
+func (parser *PreJSPy) GetLocationsEnabled() bool {
+	return parser.locations
+}
+
+// SetRegexLiteralsEnabled sets whether `/pattern/flags` regex literals are
+// parsed as a RegexLiteral. Disabled by default, in which case `/` is only
+// ever treated as the division binary operator.
+func (parser *PreJSPy) SetRegexLiteralsEnabled(enabled bool) {
+	parser.regexLiterals = enabled
+}
+
+// GetRegexLiteralsEnabled returns whether `/pattern/flags` regex literals are
+// parsed as a RegexLiteral.
+func (parser *PreJSPy) GetRegexLiteralsEnabled() bool {
+	return parser.regexLiterals
+}
+
 // =========
 // INIT CODE
 // =========
@@ -107,12 +137,43 @@ func (parser *PreJSPy) binaryPrecendence(op_val string) int {
 // Parsing
 // =======
 
+// Parse parses expr into an Expression. It panics with a *ParseError if expr
+// is malformed; use ParseSafe to parse without panicking.
 func (parser *PreJSPy) Parse(expr string) Expression {
+	result, err := parser.ParseSafe(expr)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// ParseSafe parses expr into an Expression, returning a *ParseError instead
+// of panicking if expr is malformed.
+func (parser *PreJSPy) ParseSafe(expr string) (result Expression, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			parseErr, ok := r.(*ParseError)
+			if !ok {
+				panic(r)
+			}
+			err = parseErr
+		}
+	}()
+
+	return parser.parse(expr), nil
+}
+
+func (parser *PreJSPy) parse(expr string) Expression {
 	var index, length int
+	locsEnabled := parser.locations
+
+	throwError := func(message string, index int) {
+		panic(newParseError(message, index, expr))
+	}
 
 	var exprI func(int) string
 	var exprICode func(int) int
-	var gobbleExpression, gobbleBinaryExpression, gobbleToken, gobbleNumericLiteral, gobbleStringLiteral, gobbleGroup, gobbleArray, gobbleVariable, gobbleIdentifier func() Expression
+	var gobbleExpression, gobbleBinaryExpression, gobbleToken, gobbleNumericLiteral, gobbleStringLiteral, gobbleGroup, gobbleArray, gobbleVariable, gobbleIdentifier, gobbleRegexLiteral func() Expression
 	var gobbleArguments func(termination int) (args []Expression)
 
 	// `index` stores the character number we are currently at while `length` is a constant
@@ -144,6 +205,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 	// The main parsing function. Much of this code is dedicated to ternary expressions
 	gobbleExpression = func() Expression {
+		start := index
 		var test = gobbleBinaryExpression()
 		var consequent, alternate Expression
 
@@ -153,25 +215,26 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			index++
 			consequent = gobbleExpression()
 			if consequent == nil {
-				ThrowError("Expected expression", index)
+				throwError("Expected expression", index)
 			}
 			gobbleSpaces()
 			if exprICode(index) == COLON_CODE {
 				index++
 				alternate = gobbleExpression()
 				if alternate == nil {
-					ThrowError("Expected expression", index)
+					throwError("Expected expression", index)
 				}
 				if !parser.GetTertiaryOperatorEnabled() {
-					ThrowError("Unexpected tertiary operator", index)
+					throwError("Unexpected tertiary operator", index)
 				}
 				return ConditionalExpression{
+					nodeBase:   nodeBase{start: start, end: alternate.End(), locsEnabled: locsEnabled},
 					Test:       test,
 					Consequent: consequent,
 					Alternate:  alternate,
 				}
 			} else {
-				ThrowError("Expected :", index)
+				throwError("Expected :", index)
 			}
 		} else {
 			return test
@@ -227,7 +290,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 		right := gobbleToken()
 		if right == nil {
-			ThrowError("Expected expression after "+biop, index)
+			throwError("Expected expression after "+biop, index)
 		}
 
 		stack := []Expression{left, biop_info, right}
@@ -250,6 +313,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 				biop = op.(binaryOperator).Value
 
 				stack = append(stack, BinaryExpression{
+					nodeBase: nodeBase{start: left.Pos(), end: right.End(), locsEnabled: locsEnabled},
 					Operator: biop,
 					Left:     left,
 					Right:    right,
@@ -258,7 +322,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 			node = gobbleToken()
 			if node == nil {
-				ThrowError("Expected expression after "+biop, index)
+				throwError("Expected expression after "+biop, index)
 			}
 			stack = append(stack, biop_info, node)
 
@@ -268,9 +332,11 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 		i := len(stack) - 1
 		node = stack[i]
 		for i > 1 {
+			left := stack[i-2]
 			node = BinaryExpression{
+				nodeBase: nodeBase{start: left.Pos(), end: node.End(), locsEnabled: locsEnabled},
 				Operator: stack[i-1].(binaryOperator).Value,
-				Left:     stack[i-2],
+				Left:     left,
 				Right:    node,
 			}
 			i -= 2
@@ -293,16 +359,25 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			return gobbleStringLiteral()
 		} else if ch == OBRACK_CODE {
 			return gobbleArray()
+		} else if ch == SLASH_CODE && parser.GetRegexLiteralsEnabled() {
+			return gobbleRegexLiteral()
 		} else {
 			to_check = substring(expr, index, parser.GetMaxUnaryOperatorsLength())
 			tc_len = strlen(to_check)
 
 			for tc_len > 0 {
 				if contains(u_ops, to_check) {
+					start := index
 					index += tc_len
+					argument := gobbleToken()
+					end := index
+					if argument != nil {
+						end = argument.End()
+					}
 					return UnaryExpression{
+						nodeBase: nodeBase{start: start, end: end, locsEnabled: locsEnabled},
 						Operator: to_check,
-						Argument: gobbleToken(),
+						Argument: argument,
 					}
 				}
 
@@ -319,6 +394,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 	}
 
 	gobbleNumericLiteral = func() Expression {
+		start := index
 		var number, ch string
 		var chCode int
 		for isDecimalDigit(exprICode(index)) {
@@ -352,7 +428,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			}
 
 			if !isDecimalDigit(exprICode(index - 1)) {
-				ThrowError("Expected exponent ("+number+exprI(index)+")", index)
+				throwError("Expected exponent ("+number+exprI(index)+")", index)
 			}
 
 		}
@@ -361,9 +437,9 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 		// Check to make sure this isn't a variable name that start with a number (123abc)
 		if isIdentifierStart(chCode) {
-			ThrowError("Variable names cannot start with a number ("+number+exprI(index)+")", index)
+			throwError("Variable names cannot start with a number ("+number+exprI(index)+")", index)
 		} else if chCode == PERIOD_CODE {
-			ThrowError("Unexpected period", index)
+			throwError("Unexpected period", index)
 		}
 
 		// parse the number
@@ -373,14 +449,16 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 		}
 
 		return Literal{
-			Value: floatNumber,
-			Raw:   number,
+			nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+			Value:    floatNumber,
+			Raw:      number,
 		}
 	}
 
 	// Parses a string literal, staring with single or double quotes with basic support for escape codes
 	// e.g. `"hello world"`, `'this is\nJSEP'`
 	gobbleStringLiteral = func() Expression {
+		start := index
 		var str string
 		quote := exprI(index)
 		index++
@@ -422,12 +500,64 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 		}
 
 		if !closed {
-			ThrowError("Unclosed quote after \""+str+"\"", index)
+			throwError("Unclosed quote after \""+str+"\"", index)
 		}
 
 		return Literal{
-			Value: str,
-			Raw:   quote + str + quote,
+			nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+			Value:    str,
+			Raw:      quote + str + quote,
+		}
+	}
+
+	// Parses a regular-expression literal, starting with a `/` and running
+	// until a matching unescaped `/`, followed by any number of identifier
+	// characters as flags. Only called when regex literals are enabled.
+	// e.g. `/abc/`, `/a\/b/gi`
+	gobbleRegexLiteral = func() Expression {
+		start := index
+		index++ // skip the opening slash
+
+		var pattern string
+		var closed, inClass bool
+		var ch string
+
+		for index < length {
+			ch = exprI(index)
+			index++
+			if ch == "\\" {
+				pattern += ch + exprI(index)
+				index++
+			} else if ch == "[" {
+				inClass = true
+				pattern += ch
+			} else if ch == "]" {
+				inClass = false
+				pattern += ch
+			} else if ch == "/" && !inClass {
+				closed = true
+				break
+			} else if ch == "\n" {
+				break
+			} else {
+				pattern += ch
+			}
+		}
+
+		if !closed {
+			throwError("Unclosed regular expression", index)
+		}
+
+		flagsStart := index
+		for isIdentifierPart(exprICode(index)) {
+			index++
+		}
+
+		return RegexLiteral{
+			nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+			Pattern:  pattern,
+			Flags:    substring(expr, flagsStart, index-flagsStart),
+			Raw:      substring(expr, start, index-start),
 		}
 	}
 
@@ -442,7 +572,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 		if isIdentifierStart(ch) {
 			index++
 		} else {
-			ThrowError("Unexpected "+exprI(index), index)
+			throwError("Unexpected "+exprI(index), index)
 		}
 
 		for index < length {
@@ -457,12 +587,14 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 		if c, ok := constants[identifier]; ok {
 			return Literal{
-				Value: c,
-				Raw:   identifier,
+				nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+				Value:    c,
+				Raw:      identifier,
 			}
 		} else {
 			return Identifier{
-				Name: identifier,
+				nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+				Name:     identifier,
 			}
 		}
 	}
@@ -486,7 +618,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			} else {
 				node := gobbleExpression()
 				if node == nil || node.Type() == COMPOUND {
-					ThrowError("Expected comma", index)
+					throwError("Expected comma", index)
 				}
 				args = append(args, node)
 			}
@@ -504,6 +636,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 	// It also gobbles function calls:
 	// e.g. `Math.acos(obj.angle)`
 	gobbleVariable = func() (node Expression) {
+		start := index
 
 		ch_i := exprICode(index)
 		if ch_i == OPAREN_CODE {
@@ -519,27 +652,33 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 
 			if ch_i == PERIOD_CODE {
 				gobbleSpaces()
+				property := gobbleIdentifier()
 				node = MemberExpression{
+					nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
 					Computed: false,
 					Object:   node,
-					Property: gobbleIdentifier(),
+					Property: property,
 				}
 			} else if ch_i == OBRACK_CODE {
-				node = MemberExpression{
-					Computed: true,
-					Object:   node,
-					Property: gobbleExpression(),
-				}
+				property := gobbleExpression()
 				gobbleSpaces()
 				ch_i = exprICode(index)
 				if ch_i != CBRACK_CODE {
-					ThrowError("Unclosed [", index)
+					throwError("Unclosed [", index)
 				}
 				index++
+				node = MemberExpression{
+					nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+					Computed: true,
+					Object:   node,
+					Property: property,
+				}
 			} else if ch_i == OPAREN_CODE {
 				// A function call is being made; gobble all the arguments
+				arguments := gobbleArguments(CPAREN_CODE)
 				node = CallExpression{
-					Arguments: gobbleArguments(CPAREN_CODE),
+					nodeBase:  nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+					Arguments: arguments,
 					Callee:    node,
 				}
 			}
@@ -562,7 +701,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			index++
 			return node
 		} else {
-			ThrowError("Unclosed (", index)
+			throwError("Unclosed (", index)
 		}
 		return nil
 	}
@@ -571,9 +710,12 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 	// This function assumes that it needs to gobble the opening bracket
 	// and then tries to gobble the expressions as arguments.
 	gobbleArray = func() Expression {
+		start := index
 		index++
+		elements := gobbleArguments(CBRACK_CODE)
 		return ArrayExpression{
-			Elements: gobbleArguments(CBRACK_CODE),
+			nodeBase: nodeBase{start: start, end: index, locsEnabled: locsEnabled},
+			Elements: elements,
 		}
 	}
 
@@ -592,7 +734,7 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 			if node != nil {
 				nodes = append(nodes, node)
 			} else if index < length {
-				ThrowError("Unexpected \""+exprI(index)+"\"", index)
+				throwError("Unexpected \""+exprI(index)+"\"", index)
 			}
 		}
 	}
@@ -602,7 +744,8 @@ func (parser *PreJSPy) Parse(expr string) Expression {
 		return nodes[0]
 	} else {
 		return Compound{
-			Body: nodes,
+			nodeBase: nodeBase{start: 0, end: index, locsEnabled: locsEnabled},
+			Body:     nodes,
 		}
 	}
 }
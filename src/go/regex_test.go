@@ -0,0 +1,69 @@
+package prejspy
+
+import "testing"
+
+func TestRegexLiteralsDisabledByDefault(t *testing.T) {
+	parser := NewPreJSPy()
+
+	if parser.GetRegexLiteralsEnabled() {
+		t.Fatal("expected regex literals to be disabled by default")
+	}
+
+	expr := parser.Parse("6 / 3")
+	if _, ok := expr.(BinaryExpression); !ok {
+		t.Fatalf("expected BinaryExpression, got %T", expr)
+	}
+}
+
+func TestRegexLiteralsEnabled(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	expr := parser.Parse("/abc/gi")
+	regex, ok := expr.(RegexLiteral)
+	if !ok {
+		t.Fatalf("expected RegexLiteral, got %T", expr)
+	}
+	if regex.Pattern != "abc" {
+		t.Errorf("Pattern = %q, want %q", regex.Pattern, "abc")
+	}
+	if regex.Flags != "gi" {
+		t.Errorf("Flags = %q, want %q", regex.Flags, "gi")
+	}
+	if regex.Raw != "/abc/gi" {
+		t.Errorf("Raw = %q, want %q", regex.Raw, "/abc/gi")
+	}
+}
+
+func TestRegexLiteralsEscapesAndClasses(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	expr := parser.Parse(`/a\/b[/]c/`)
+	regex, ok := expr.(RegexLiteral)
+	if !ok {
+		t.Fatalf("expected RegexLiteral, got %T", expr)
+	}
+	if want := `a\/b[/]c`; regex.Pattern != want {
+		t.Errorf("Pattern = %q, want %q", regex.Pattern, want)
+	}
+}
+
+func TestRegexLiteralsUnclosed(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	if _, err := parser.ParseSafe("/abc"); err == nil {
+		t.Fatal("expected an error for an unclosed regular expression")
+	}
+}
+
+func TestRegexLiteralsStillDivideWhenEnabled(t *testing.T) {
+	parser := NewPreJSPy()
+	parser.SetRegexLiteralsEnabled(true)
+
+	expr := parser.Parse("6 / 3")
+	if _, ok := expr.(BinaryExpression); !ok {
+		t.Fatalf("expected BinaryExpression, got %T", expr)
+	}
+}
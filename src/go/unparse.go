@@ -0,0 +1,172 @@
+package prejspy
+
+// unparsePrecedence supplies the binary operator precedences Unparse uses to
+// decide where parentheses are required. It is a parser with the default
+// operators registered, since Unparse takes no parser of its own.
+var unparsePrecedence = NewPreJSPy()
+
+// Unparse renders expr back into a valid PreJsPy expression string. Parsing
+// the result with PreJSPy.Parse reproduces an AST equivalent to expr.
+func Unparse(expr Expression) string {
+	return unparse(unparsePrecedence, expr)
+}
+
+func unparse(parser *PreJSPy, expr Expression) string {
+	switch n := expr.(type) {
+	case Compound:
+		out := ""
+		for i, stmt := range n.Body {
+			if i > 0 {
+				out += "; "
+			}
+			out += unparse(parser, stmt)
+		}
+		return out
+	case Identifier:
+		return n.Name
+	case Literal:
+		return unparseLiteral(n)
+	case RegexLiteral:
+		return n.Raw
+	case ArrayExpression:
+		out := "["
+		for i, el := range n.Elements {
+			if i > 0 {
+				out += ", "
+			}
+			out += unparse(parser, el)
+		}
+		return out + "]"
+	case MemberExpression:
+		object := unparseMemberBase(parser, n.Object)
+		if n.Computed {
+			return object + "[" + unparse(parser, n.Property) + "]"
+		}
+		return object + "." + unparse(parser, n.Property)
+	case CallExpression:
+		callee := unparseMemberBase(parser, n.Callee)
+		args := ""
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				args += ", "
+			}
+			args += unparse(parser, arg)
+		}
+		return callee + "(" + args + ")"
+	case UnaryExpression:
+		argument := unparseToken(parser, n.Argument)
+		if needsUnarySeparator(n.Operator, argument) {
+			return n.Operator + " " + argument
+		}
+		return n.Operator + argument
+	case BinaryExpression:
+		prec := parser.binaryPrecendence(n.Operator)
+		left := unparseBinaryOperand(parser, n.Left, prec, false)
+		right := unparseBinaryOperand(parser, n.Right, prec, true)
+		return left + " " + n.Operator + " " + right
+	case ConditionalExpression:
+		test := unparse(parser, n.Test)
+		if _, ok := n.Test.(ConditionalExpression); ok {
+			test = "(" + test + ")"
+		}
+		return test + " ? " + unparse(parser, n.Consequent) + " : " + unparse(parser, n.Alternate)
+	default:
+		panic("Unparse: unexpected node type " + string(expr.Type()))
+	}
+}
+
+// unparseMemberBase renders the object of a MemberExpression or the callee of
+// a CallExpression, wrapping it in parentheses unless it is itself one of the
+// node types gobbleVariable can chain directly onto without a preceding `(`.
+func unparseMemberBase(parser *PreJSPy, expr Expression) string {
+	switch expr.(type) {
+	case Identifier, MemberExpression, CallExpression:
+		return unparse(parser, expr)
+	default:
+		return "(" + unparse(parser, expr) + ")"
+	}
+}
+
+// unparseToken renders the argument of a UnaryExpression, wrapping it in
+// parentheses if it is a node gobbleToken's unary branch could never produce
+// directly (it only ever gobbles another token as its argument).
+func unparseToken(parser *PreJSPy, expr Expression) string {
+	switch expr.(type) {
+	case BinaryExpression, ConditionalExpression:
+		return "(" + unparse(parser, expr) + ")"
+	default:
+		return unparse(parser, expr)
+	}
+}
+
+// unparseBinaryOperand renders one side of a BinaryExpression, wrapping it in
+// parentheses when its precedence (as reported by parser.binaryPrecendence)
+// is too low to be re-parsed into the same tree shape without them.
+func unparseBinaryOperand(parser *PreJSPy, operand Expression, parentPrec int, isRight bool) string {
+	switch n := operand.(type) {
+	case ConditionalExpression:
+		return "(" + unparse(parser, operand) + ")"
+	case BinaryExpression:
+		childPrec := parser.binaryPrecendence(n.Operator)
+		if childPrec < parentPrec || (isRight && childPrec == parentPrec) {
+			return "(" + unparse(parser, operand) + ")"
+		}
+	}
+	return unparse(parser, operand)
+}
+
+// unparseLiteral renders a Literal. String values are always re-quoted from
+// Value so the escape sequences match what gobbleStringLiteral understands;
+// other literals prefer Raw, falling back to a canonical rendering of Value.
+func unparseLiteral(lit Literal) string {
+	if s, ok := lit.Value.(string); ok {
+		return quoteStringLiteral(s)
+	}
+	if lit.Raw != "" {
+		return lit.Raw
+	}
+	return toString(lit.Value)
+}
+
+// quoteStringLiteral renders s as a double-quoted string literal, escaping
+// it with the same set of sequences gobbleStringLiteral decodes.
+func quoteStringLiteral(s string) string {
+	quoted := `"`
+	for _, r := range s {
+		switch r {
+		case '\n':
+			quoted += `\n`
+		case '\r':
+			quoted += `\r`
+		case '\t':
+			quoted += `\t`
+		case '\b':
+			quoted += `\b`
+		case '\f':
+			quoted += `\f`
+		case '\x0B':
+			quoted += `\v`
+		case '\\':
+			quoted += `\\`
+		case '"':
+			quoted += `\"`
+		default:
+			quoted += string(r)
+		}
+	}
+	return quoted + `"`
+}
+
+// needsUnarySeparator reports whether a space is required between operator
+// and argument to keep them from being re-tokenized as one run, e.g. for a
+// word-like unary operator immediately followed by an identifier or number.
+func needsUnarySeparator(operator, argument string) bool {
+	if operator == "" || argument == "" {
+		return false
+	}
+	opRunes := []rune(operator)
+	argRunes := []rune(argument)
+	opLast := int(opRunes[len(opRunes)-1])
+	argFirst := int(argRunes[0])
+	return isIdentifierPart(opLast) && (isIdentifierStart(argFirst) || isDecimalDigit(argFirst))
+}
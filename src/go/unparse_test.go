@@ -0,0 +1,166 @@
+package prejspy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnparse(t *testing.T) {
+	parser := NewPreJSPy()
+
+	tests := []string{
+		"1 + 2",
+		"1 + 2 * 3",
+		"(1 + 2) * 3",
+		"1 - (2 - 3)",
+		"(1 - 2) - 3",
+		"a.b.c",
+		"a[0].b",
+		"foo(1, 2, 3)",
+		"(1 + 2).toString()",
+		"-(1 + 2)",
+		"!a && -b",
+		"a ? b : c",
+		"a ? (b ? c : d) : e",
+		"(a ? b : c) + 1",
+		"[1, 2, 3]",
+		"'hello\\nworld'",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			before := parser.Parse(expr)
+			unparsed := Unparse(before)
+			after := parser.Parse(unparsed)
+
+			if !astEqual(before, after) {
+				t.Errorf("Unparse(%q) = %q, re-parsed to a different AST", expr, unparsed)
+			}
+		})
+	}
+}
+
+func TestUnparseStringEscaping(t *testing.T) {
+	lit := Literal{Value: "a\nb\tc\"d", Raw: `'unused'`}
+	if got, want := Unparse(lit), `"a\nb\tc\"d"`; got != want {
+		t.Errorf("Unparse(%v) = %q, want %q", lit, got, want)
+	}
+}
+
+func TestUnparseNumericLiteralUsesRaw(t *testing.T) {
+	lit := Literal{Value: 1.0, Raw: "1.0"}
+	if got, want := Unparse(lit), "1.0"; got != want {
+		t.Errorf("Unparse(%v) = %q, want %q", lit, got, want)
+	}
+}
+
+func TestUnparseStringer(t *testing.T) {
+	parser := NewPreJSPy()
+	expr := parser.Parse("a + b")
+	if got, want := expr.(BinaryExpression).String(), Unparse(expr); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// TestUnparseRoundTrip parses every fixture in tests/, unparses the result,
+// re-parses the unparsed string, and asserts the two ASTs are equal -- the
+// round-trip discipline otto's marshal_test.go applies to its own parser.
+func TestUnparseRoundTrip(t *testing.T) {
+	parser := NewPreJSPy()
+
+	_, filename, _, _ := runtime.Caller(0)
+	testDirectory := filepath.Join(filename, "..", "..", "..", "tests")
+	files, err := os.ReadDir(testDirectory)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, f := range files {
+		if !f.Type().IsRegular() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+
+		testfile := filepath.Join(testDirectory, f.Name())
+		t.Run(f.Name(), func(t *testing.T) {
+			cases, err := readTestFile(testfile)
+			if err != nil {
+				panic(err)
+			}
+
+			for _, c := range cases {
+				t.Run(c.Message, func(t *testing.T) {
+					before := parser.Parse(c.Input)
+					unparsed := Unparse(before)
+					after := parser.Parse(unparsed)
+
+					if !astEqual(before, after) {
+						t.Errorf("Unparse(%q) = %q, re-parsed to a different AST", c.Input, unparsed)
+					}
+				})
+			}
+		})
+	}
+}
+
+// astEqual reports whether a and b describe the same AST shape, ignoring
+// source position and the verbatim Raw text of literals -- Unparse is free
+// to reformat a literal (e.g. normalizing string quoting) as long as its
+// Value is preserved.
+func astEqual(a, b Expression) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch an := a.(type) {
+	case Compound:
+		bn := b.(Compound)
+		return exprsEqual(an.Body, bn.Body)
+	case Identifier:
+		return an.Name == b.(Identifier).Name
+	case Literal:
+		bn := b.(Literal)
+		return reflect.DeepEqual(an.Value, bn.Value)
+	case RegexLiteral:
+		bn := b.(RegexLiteral)
+		return an.Pattern == bn.Pattern && an.Flags == bn.Flags
+	case MemberExpression:
+		bn := b.(MemberExpression)
+		return an.Computed == bn.Computed && astEqual(an.Object, bn.Object) && astEqual(an.Property, bn.Property)
+	case CallExpression:
+		bn := b.(CallExpression)
+		return astEqual(an.Callee, bn.Callee) && exprsEqual(an.Arguments, bn.Arguments)
+	case UnaryExpression:
+		bn := b.(UnaryExpression)
+		return an.Operator == bn.Operator && astEqual(an.Argument, bn.Argument)
+	case BinaryExpression:
+		bn := b.(BinaryExpression)
+		return an.Operator == bn.Operator && astEqual(an.Left, bn.Left) && astEqual(an.Right, bn.Right)
+	case ConditionalExpression:
+		bn := b.(ConditionalExpression)
+		return astEqual(an.Test, bn.Test) && astEqual(an.Consequent, bn.Consequent) && astEqual(an.Alternate, bn.Alternate)
+	case ArrayExpression:
+		bn := b.(ArrayExpression)
+		return exprsEqual(an.Elements, bn.Elements)
+	default:
+		return false
+	}
+}
+
+func exprsEqual(a, b []Expression) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !astEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,13 +1,5 @@
 package prejspy
 
-import "fmt"
-
-// ThrowError throws an error
-func ThrowError(message string, index int) {
-	err := fmt.Sprintf("%s at character %d", message, index)
-	panic(err)
-}
-
 //     // TODO: Create utility functions for all the other return values also
 //     // just so that we are consistent.
 
@@ -0,0 +1,235 @@
+package prejspy
+
+// Visitor visits Expression nodes, in the style of go/ast.Visitor.
+//
+// If the result w of Visit is not nil, Walk visits each of the children of
+// node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Expression) (w Visitor)
+}
+
+// Walk traverses an Expression tree in depth-first order: it starts by
+// calling v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the children of node, followed by a call of w.Visit(nil).
+func Walk(v Visitor, node Expression) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case Compound:
+		for _, expr := range n.Body {
+			Walk(v, expr)
+		}
+	case Identifier:
+		// no children
+	case Literal:
+		// no children
+	case RegexLiteral:
+		// no children
+	case MemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+	case CallExpression:
+		Walk(v, n.Callee)
+		for _, arg := range n.Arguments {
+			Walk(v, arg)
+		}
+	case UnaryExpression:
+		Walk(v, n.Argument)
+	case BinaryExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case ConditionalExpression:
+		Walk(v, n.Test)
+		Walk(v, n.Consequent)
+		Walk(v, n.Alternate)
+	case ArrayExpression:
+		for _, el := range n.Elements {
+			Walk(v, el)
+		}
+	default:
+		panic("Walk: unexpected node type " + string(node.Type()))
+	}
+
+	v.Visit(nil)
+}
+
+// inspector implements Visitor by calling f for every visited node, in the
+// style of go/ast.Inspect.
+type inspector func(Expression) bool
+
+func (f inspector) Visit(node Expression) Visitor {
+	if node == nil {
+		return nil
+	}
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an Expression tree in depth-first order: it starts by
+// calling f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the children of node. Unlike go/ast.Inspect, f is
+// never called with nil.
+func Inspect(node Expression, f func(Expression) bool) {
+	Walk(inspector(f), node)
+}
+
+// CollectIdentifiers returns the names of every Identifier reachable from root, in
+// the order in which they are visited. Duplicate names are included once each
+// time they occur.
+func CollectIdentifiers(root Expression) []string {
+	var names []string
+	Inspect(root, func(node Expression) bool {
+		if ident, ok := node.(Identifier); ok {
+			names = append(names, ident.Name)
+		}
+		return true
+	})
+	return names
+}
+
+// ReplaceIdentifier returns a copy of root with every Identifier named name
+// replaced by replacement. The Property of a non-computed MemberExpression
+// (the `b` in `a.b`) is left unchanged.
+func ReplaceIdentifier(root Expression, name string, replacement Expression) Expression {
+	switch n := root.(type) {
+	case Identifier:
+		if n.Name == name {
+			return replacement
+		}
+		return n
+	case Compound:
+		n.Body = replaceAll(n.Body, name, replacement)
+		return n
+	case Literal:
+		return n
+	case RegexLiteral:
+		return n
+	case MemberExpression:
+		n.Object = ReplaceIdentifier(n.Object, name, replacement)
+		if n.Computed {
+			n.Property = ReplaceIdentifier(n.Property, name, replacement)
+		}
+		return n
+	case CallExpression:
+		n.Callee = ReplaceIdentifier(n.Callee, name, replacement)
+		n.Arguments = replaceAll(n.Arguments, name, replacement)
+		return n
+	case UnaryExpression:
+		n.Argument = ReplaceIdentifier(n.Argument, name, replacement)
+		return n
+	case BinaryExpression:
+		n.Left = ReplaceIdentifier(n.Left, name, replacement)
+		n.Right = ReplaceIdentifier(n.Right, name, replacement)
+		return n
+	case ConditionalExpression:
+		n.Test = ReplaceIdentifier(n.Test, name, replacement)
+		n.Consequent = ReplaceIdentifier(n.Consequent, name, replacement)
+		n.Alternate = ReplaceIdentifier(n.Alternate, name, replacement)
+		return n
+	case ArrayExpression:
+		n.Elements = replaceAll(n.Elements, name, replacement)
+		return n
+	default:
+		return root
+	}
+}
+
+func replaceAll(exprs []Expression, name string, replacement Expression) []Expression {
+	if exprs == nil {
+		return nil
+	}
+	out := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		out[i] = ReplaceIdentifier(expr, name, replacement)
+	}
+	return out
+}
+
+// ConstantFold returns a copy of root with every Identifier found in
+// constants replaced by the corresponding Literal, and every
+// UnaryExpression, BinaryExpression and ConditionalExpression whose operands
+// are now Literals folded into a single Literal by evaluating it. The Property
+// of a non-computed MemberExpression is left unchanged, and a node whose
+// evaluation fails (e.g. `1 / 0`) is kept as it is.
+func ConstantFold(root Expression, constants map[string]interface{}) Expression {
+	return foldChildren(root, constants)
+}
+
+// foldChildren recursively folds the children of root, then folds root
+// itself if all of its children are now Literals or it is an Identifier
+// found in constants.
+func foldChildren(root Expression, constants map[string]interface{}) Expression {
+	switch n := root.(type) {
+	case Identifier:
+		if value, ok := constants[n.Name]; ok {
+			return Literal{Value: value, Raw: toString(value)}
+		}
+		return n
+	case Literal:
+		return n
+	case RegexLiteral:
+		return n
+	case Compound:
+		n.Body = foldAll(n.Body, constants)
+		return n
+	case MemberExpression:
+		n.Object = foldChildren(n.Object, constants)
+		if n.Computed {
+			n.Property = foldChildren(n.Property, constants)
+		}
+		return n
+	case CallExpression:
+		n.Callee = foldChildren(n.Callee, constants)
+		n.Arguments = foldAll(n.Arguments, constants)
+		return n
+	case UnaryExpression:
+		n.Argument = foldChildren(n.Argument, constants)
+		return foldIfConstant(n, n.Argument)
+	case BinaryExpression:
+		n.Left = foldChildren(n.Left, constants)
+		n.Right = foldChildren(n.Right, constants)
+		return foldIfConstant(n, n.Left, n.Right)
+	case ConditionalExpression:
+		n.Test = foldChildren(n.Test, constants)
+		n.Consequent = foldChildren(n.Consequent, constants)
+		n.Alternate = foldChildren(n.Alternate, constants)
+		return foldIfConstant(n, n.Test, n.Consequent, n.Alternate)
+	case ArrayExpression:
+		n.Elements = foldAll(n.Elements, constants)
+		return n
+	default:
+		return root
+	}
+}
+
+func foldAll(exprs []Expression, constants map[string]interface{}) []Expression {
+	if exprs == nil {
+		return nil
+	}
+	out := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		out[i] = foldChildren(expr, constants)
+	}
+	return out
+}
+
+// foldIfConstant evaluates node if every one of operands is a Literal,
+// returning a single Literal in its place. Otherwise, or if evaluating node
+// fails, node is returned as is.
+func foldIfConstant(node Expression, operands ...Expression) Expression {
+	for _, operand := range operands {
+		if _, ok := operand.(Literal); !ok {
+			return node
+		}
+	}
+	value, err := NewEvaluator(nil).Eval(node)
+	if err != nil {
+		return node
+	}
+	return Literal{Value: value, Raw: toString(value)}
+}
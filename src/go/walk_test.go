@@ -0,0 +1,63 @@
+package prejspy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectIdentifiers(t *testing.T) {
+	parser := NewPreJSPy()
+
+	expr := parser.Parse("a + b.c * foo(d)")
+	got := CollectIdentifiers(expr)
+	want := []string{"a", "b", "c", "foo", "d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectIdentifiers() = %v, want %v", got, want)
+	}
+}
+
+func TestReplaceIdentifier(t *testing.T) {
+	parser := NewPreJSPy()
+
+	expr := parser.Parse("a + 1")
+	replaced := ReplaceIdentifier(expr, "a", Literal{Value: 41.0, Raw: "41"})
+
+	value, err := NewEvaluator(nil).Eval(replaced)
+	if err != nil {
+		t.Fatalf("Eval returned error: %v", err)
+	}
+	if value != 42.0 {
+		t.Errorf("Eval(ReplaceIdentifier(...)) = %v, want 42", value)
+	}
+}
+
+func TestConstantFold(t *testing.T) {
+	parser := NewPreJSPy()
+
+	expr := parser.Parse("1 + x * 2")
+	folded := ConstantFold(expr, map[string]interface{}{"x": 10.0})
+
+	binary, ok := folded.(Literal)
+	if !ok {
+		t.Fatalf("ConstantFold(...) = %T, want Literal", folded)
+	}
+	if binary.Value != 21.0 {
+		t.Errorf("ConstantFold(...).Value = %v, want 21", binary.Value)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	parser := NewPreJSPy()
+
+	expr := parser.Parse("a + b")
+	var kinds []ASTKind
+	Inspect(expr, func(node Expression) bool {
+		kinds = append(kinds, node.Type())
+		return true
+	})
+
+	want := []ASTKind{BINARY_EXP, IDENTIFIER, IDENTIFIER}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Inspect visited %v, want %v", kinds, want)
+	}
+}